@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gotd/td/telegram/message"
+	"github.com/gotd/td/tg"
+)
+
+// adminAPIServer is the optional embedded HTTP server that exposes
+// status, metrics and manual overrides for a running TelegramDaemon.
+type adminAPIServer struct {
+	daemon *TelegramDaemon
+	server *http.Server
+}
+
+// newAdminAPIServer builds (but does not start) the admin API server for
+// d, wiring up every route under a bearer-token check.
+func newAdminAPIServer(d *TelegramDaemon) *adminAPIServer {
+	a := &adminAPIServer{daemon: d}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", a.authenticated(a.handleStatus))
+	mux.HandleFunc("/responded", a.authenticated(a.handleResponded))
+	mux.HandleFunc("/responded/", a.authenticated(a.handleRespondedReset))
+	mux.HandleFunc("/contacts/reload", a.authenticated(a.handleContactsReload))
+	mux.HandleFunc("/config/reload", a.authenticated(a.handleConfigReload))
+	mux.HandleFunc("/send", a.authenticated(a.handleSend))
+	mux.HandleFunc("/metrics", a.authenticated(a.handleMetrics))
+
+	a.server = &http.Server{
+		Addr:    d.config.AdminAPI.Listen,
+		Handler: mux,
+	}
+	return a
+}
+
+// Run starts serving and blocks until the server stops. It is meant to
+// be called in its own goroutine from Start.
+func (a *adminAPIServer) Run() {
+	log.Printf("Admin API listening on %s", a.daemon.config.AdminAPI.Listen)
+	if err := a.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Printf("Admin API server stopped: %v", err)
+	}
+}
+
+// Shutdown gracefully stops the admin API server.
+func (a *adminAPIServer) Shutdown(ctx context.Context) error {
+	return a.server.Shutdown(ctx)
+}
+
+// authenticated wraps h with a bearer-token check against
+// config.AdminAPI.Token.
+func (a *adminAPIServer) authenticated(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		want := "Bearer " + a.daemon.config.AdminAPI.Token
+		got := r.Header.Get("Authorization")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(want)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Admin API: failed to encode response: %v", err)
+	}
+}
+
+// handleStatus implements GET /status.
+func (a *adminAPIServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	d := a.daemon
+	d.responseMutex.RLock()
+	respondedCount := len(d.respondedUsers)
+	d.responseMutex.RUnlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"auth_state":      "authenticated",
+		"uptime_seconds":  int(time.Since(d.startTime).Seconds()),
+		"contacts_count":  len(d.getContactIDs()),
+		"responded_count": respondedCount,
+	})
+}
+
+// handleResponded implements GET /responded.
+func (a *adminAPIServer) handleResponded(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	d := a.daemon
+	d.responseMutex.RLock()
+	type entry struct {
+		UserID       int64     `json:"user_id"`
+		LastResponse time.Time `json:"last_response"`
+	}
+	entries := make([]entry, 0, len(d.respondedUsers))
+	for id, t := range d.respondedUsers {
+		entries = append(entries, entry{UserID: id, LastResponse: t})
+	}
+	d.responseMutex.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].UserID < entries[j].UserID })
+	writeJSON(w, http.StatusOK, entries)
+}
+
+// handleRespondedReset implements POST /responded/{id}/reset.
+func (a *adminAPIServer) handleRespondedReset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/responded/")
+	idStr := strings.TrimSuffix(path, "/reset")
+	userID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	d := a.daemon
+	d.responseMutex.Lock()
+	delete(d.respondedUsers, userID)
+	d.responseMutex.Unlock()
+	d.persistState()
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "reset"})
+}
+
+// handleContactsReload implements POST /contacts/reload.
+func (a *adminAPIServer) handleContactsReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := a.daemon.loadContacts(r.Context()); err != nil {
+		http.Error(w, fmt.Sprintf("reload failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "reloaded"})
+}
+
+// handleConfigReload implements POST /config/reload. Only the fields
+// that can be swapped without dropping the Telegram session are
+// refreshed: ResponseMsg, ResponseTimeoutHours and LogLevel.
+func (a *adminAPIServer) handleConfigReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := a.daemon.reloadHotConfig(); err != nil {
+		http.Error(w, fmt.Sprintf("reload failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "reloaded"})
+}
+
+// sendRequest is the body of POST /send.
+type sendRequest struct {
+	UserID int64  `json:"user_id"`
+	Text   string `json:"text"`
+}
+
+// handleSend implements POST /send for out-of-band manual messages.
+func (a *adminAPIServer) handleSend(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req sendRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.UserID == 0 || req.Text == "" {
+		http.Error(w, "user_id and text are required", http.StatusBadRequest)
+		return
+	}
+
+	d := a.daemon
+	sender := message.NewSender(d.client.API())
+	if _, err := sender.To(&tg.InputPeerUser{UserID: req.UserID}).Text(r.Context(), req.Text); err != nil {
+		http.Error(w, fmt.Sprintf("send failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "sent"})
+}
+
+// handleMetrics implements GET /metrics in a minimal Prometheus text
+// exposition format.
+func (a *adminAPIServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	m := a.daemon.metrics
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprintf(w, "# HELP no_more_telegram_messages_received_total Private messages seen.\n")
+	fmt.Fprintf(w, "# TYPE no_more_telegram_messages_received_total counter\n")
+	fmt.Fprintf(w, "no_more_telegram_messages_received_total %d\n", atomic.LoadInt64(&m.messagesReceived))
+	fmt.Fprintf(w, "# HELP no_more_telegram_replies_sent_total Auto-replies sent.\n")
+	fmt.Fprintf(w, "# TYPE no_more_telegram_replies_sent_total counter\n")
+	fmt.Fprintf(w, "no_more_telegram_replies_sent_total %d\n", atomic.LoadInt64(&m.repliesSent))
+	fmt.Fprintf(w, "# HELP no_more_telegram_flood_wait_retries_total FLOOD_WAIT retries performed.\n")
+	fmt.Fprintf(w, "# TYPE no_more_telegram_flood_wait_retries_total counter\n")
+	fmt.Fprintf(w, "no_more_telegram_flood_wait_retries_total %d\n", atomic.LoadInt64(&m.floodWaitRetries))
+}
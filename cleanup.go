@@ -0,0 +1,297 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+)
+
+// cleanupDialogsPageSize is how many dialogs we request per
+// MessagesGetDialogs call while paginating.
+const cleanupDialogsPageSize = 100
+
+// cleanupMaxRetries bounds how many times a single cleanup call is
+// attempted while it keeps failing with a flood-wait before it is given
+// up on.
+const cleanupMaxRetries = 5
+
+// runCleanup reduces the user's Telegram footprint on startup according
+// to config.Cleanup: leaving groups/channels, archiving whatever dialogs
+// remain, and muting everything. Every Telegram call goes through
+// withFloodWaitRetry so a rate limit pauses the whole pass instead of
+// aborting it.
+func (d *TelegramDaemon) runCleanup(ctx context.Context) error {
+	cfg := d.config.Cleanup
+	if cfg.DryRun {
+		log.Println("Cleanup subsystem running in dry-run mode, no changes will be made")
+	}
+
+	dialogs, err := d.fetchAllDialogs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch dialogs: %w", err)
+	}
+	log.Printf("Cleanup: found %d dialogs", len(dialogs))
+
+	for _, dialog := range dialogs {
+		if err := d.cleanupDialog(ctx, cfg, dialog); err != nil {
+			log.Printf("Cleanup: failed to process dialog %T: %v", dialog, err)
+		}
+	}
+
+	return nil
+}
+
+// fetchAllDialogs walks MessagesGetDialogs to completion, following the
+// offset_id/offset_date/offset_peer pagination Telegram requires.
+func (d *TelegramDaemon) fetchAllDialogs(ctx context.Context) ([]tg.DialogClass, error) {
+	api := d.client.API()
+
+	var (
+		all        []tg.DialogClass
+		offsetID   int
+		offsetDate int
+		offsetPeer tg.InputPeerClass = &tg.InputPeerEmpty{}
+	)
+
+	for {
+		var result tg.MessagesDialogsClass
+		err := d.withFloodWaitRetry(ctx, "MessagesGetDialogs", func() error {
+			var callErr error
+			result, callErr = api.MessagesGetDialogs(ctx, &tg.MessagesGetDialogsRequest{
+				OffsetDate: offsetDate,
+				OffsetID:   offsetID,
+				OffsetPeer: offsetPeer,
+				Limit:      cleanupDialogsPageSize,
+			})
+			return callErr
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var dialogs []tg.DialogClass
+		var messages []tg.MessageClass
+		switch r := result.(type) {
+		case *tg.MessagesDialogs:
+			dialogs, messages = r.Dialogs, r.Messages
+		case *tg.MessagesDialogsSlice:
+			dialogs, messages = r.Dialogs, r.Messages
+		default:
+			return nil, fmt.Errorf("unexpected dialogs result type: %T", result)
+		}
+
+		all = append(all, dialogs...)
+		if len(dialogs) < cleanupDialogsPageSize {
+			return all, nil
+		}
+
+		if len(messages) == 0 {
+			return all, nil
+		}
+
+		last := messages[len(messages)-1]
+		msg, ok := last.(*tg.Message)
+		if !ok {
+			return all, nil
+		}
+
+		lastDialog, ok := dialogs[len(dialogs)-1].(*tg.Dialog)
+		if !ok {
+			return all, nil
+		}
+		offsetID = msg.ID
+		offsetDate = msg.Date
+		offsetPeer = peerToInputPeer(lastDialog.Peer)
+	}
+}
+
+// peerToInputPeer converts the peer embedded in a dialog into the
+// InputPeer variant the next MessagesGetDialogs call needs.
+func peerToInputPeer(peer tg.PeerClass) tg.InputPeerClass {
+	switch p := peer.(type) {
+	case *tg.PeerUser:
+		return &tg.InputPeerUser{UserID: p.UserID}
+	case *tg.PeerChat:
+		return &tg.InputPeerChat{ChatID: p.ChatID}
+	case *tg.PeerChannel:
+		return &tg.InputPeerChannel{ChannelID: p.ChannelID}
+	default:
+		return &tg.InputPeerEmpty{}
+	}
+}
+
+// cleanupDialog applies the configured cleanup actions to a single
+// dialog: leaving the group/channel it belongs to, then, if it is still
+// around afterwards, archiving and muting it.
+func (d *TelegramDaemon) cleanupDialog(ctx context.Context, cfg CleanupConfig, dialog tg.DialogClass) error {
+	dlg, ok := dialog.(*tg.Dialog)
+	if !ok {
+		return nil
+	}
+
+	left, err := d.leaveIfConfigured(ctx, cfg, dlg.Peer)
+	if err != nil {
+		return err
+	}
+	if left {
+		return nil
+	}
+
+	if cfg.ArchiveDialogs {
+		if err := d.archiveDialog(ctx, cfg.DryRun, dlg.Peer); err != nil {
+			return fmt.Errorf("archive: %w", err)
+		}
+	}
+
+	if cfg.MuteAll {
+		if err := d.muteDialog(ctx, cfg.DryRun, dlg.Peer); err != nil {
+			return fmt.Errorf("mute: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// leaveIfConfigured leaves a supergroup/channel or basic group dialog
+// per cfg.LeaveChannels/LeaveGroups, reporting whether it left anything.
+func (d *TelegramDaemon) leaveIfConfigured(ctx context.Context, cfg CleanupConfig, peer tg.PeerClass) (bool, error) {
+	api := d.client.API()
+
+	switch p := peer.(type) {
+	case *tg.PeerChannel:
+		if !cfg.LeaveChannels {
+			return false, nil
+		}
+		if cfg.DryRun {
+			log.Printf("[dry-run] would leave channel %d", p.ChannelID)
+			return true, nil
+		}
+		return true, d.withFloodWaitRetry(ctx, "ChannelsLeaveChannel", func() error {
+			_, err := api.ChannelsLeaveChannel(ctx, &tg.InputChannel{ChannelID: p.ChannelID})
+			return err
+		})
+
+	case *tg.PeerChat:
+		if !cfg.LeaveGroups {
+			return false, nil
+		}
+		if cfg.DryRun {
+			log.Printf("[dry-run] would leave chat %d", p.ChatID)
+			return true, nil
+		}
+		var self *tg.User
+		err := d.withFloodWaitRetry(ctx, "Self", func() error {
+			var selfErr error
+			self, selfErr = d.client.Self(ctx)
+			return selfErr
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to resolve self for MessagesDeleteChatUser: %w", err)
+		}
+		return true, d.withFloodWaitRetry(ctx, "MessagesDeleteChatUser", func() error {
+			_, err := api.MessagesDeleteChatUser(ctx, &tg.MessagesDeleteChatUserRequest{
+				ChatID: p.ChatID,
+				UserID: &tg.InputUser{UserID: self.ID, AccessHash: self.AccessHash},
+			})
+			return err
+		})
+
+	default:
+		return false, nil
+	}
+}
+
+// archiveDialog moves a dialog into the archive folder.
+func (d *TelegramDaemon) archiveDialog(ctx context.Context, dryRun bool, peer tg.PeerClass) error {
+	if dryRun {
+		log.Printf("[dry-run] would archive dialog %T", peer)
+		return nil
+	}
+
+	api := d.client.API()
+	return d.withFloodWaitRetry(ctx, "FoldersEditPeerFolders", func() error {
+		_, err := api.FoldersEditPeerFolders(ctx, []tg.InputFolderPeer{
+			{Peer: peerToInputPeer(peer), FolderID: 1}, // folder 1 is the built-in archive
+		})
+		return err
+	})
+}
+
+// muteDialog mutes a dialog indefinitely.
+func (d *TelegramDaemon) muteDialog(ctx context.Context, dryRun bool, peer tg.PeerClass) error {
+	if dryRun {
+		log.Printf("[dry-run] would mute dialog %T", peer)
+		return nil
+	}
+
+	api := d.client.API()
+	return d.withFloodWaitRetry(ctx, "AccountUpdateNotifySettings", func() error {
+		_, err := api.AccountUpdateNotifySettings(ctx, &tg.AccountUpdateNotifySettingsRequest{
+			Peer: &tg.InputNotifyPeer{Peer: peerToInputPeer(peer)},
+			Settings: tg.InputPeerNotifySettings{
+				MuteUntil: math.MaxInt32,
+			},
+		})
+		return err
+	})
+}
+
+// deleteHistoryAfterReply removes the conversation history with a
+// contact once the auto-reply to them has been confirmed sent, without
+// revoking it for the other side.
+func (d *TelegramDaemon) deleteHistoryAfterReply(ctx context.Context, userID int64) error {
+	if !d.config.Cleanup.DryRun {
+		api := d.client.API()
+		return d.withFloodWaitRetry(ctx, "MessagesDeleteHistory", func() error {
+			_, err := api.MessagesDeleteHistory(ctx, &tg.MessagesDeleteHistoryRequest{
+				Peer:   &tg.InputPeerUser{UserID: userID},
+				Revoke: false,
+			})
+			return err
+		})
+	}
+
+	log.Printf("[dry-run] would delete history with user %d", userID)
+	return nil
+}
+
+// withFloodWaitRetry calls fn, retrying with exponential backoff (seeded
+// by Telegram's own requested wait time) whenever fn fails with a
+// FLOOD_WAIT error, up to cleanupMaxRetries attempts.
+func (d *TelegramDaemon) withFloodWaitRetry(ctx context.Context, desc string, fn func() error) error {
+	backoff := time.Second
+
+	for attempt := 1; attempt <= cleanupMaxRetries; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+
+		wait, ok := tgerr.AsFloodWait(err)
+		if !ok {
+			return fmt.Errorf("%s: %w", desc, err)
+		}
+		if wait > backoff {
+			backoff = wait
+		}
+		if attempt == cleanupMaxRetries {
+			break
+		}
+
+		d.metrics.incFloodWaitRetries()
+		log.Printf("%s: flood-wait, retrying in %s (attempt %d/%d)", desc, backoff, attempt, cleanupMaxRetries)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+
+	return fmt.Errorf("%s: exceeded flood-wait retry attempts", desc)
+}
@@ -0,0 +1,133 @@
+// Package forwarders mirrors every inbound private message to a set of
+// configured sinks (email, webhook, XMPP, ...) so the user still sees
+// what people sent them after "leaving" Telegram.
+package forwarders
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Event is the structured representation of an inbound private message
+// handed to every configured Forwarder.
+type Event struct {
+	SenderName  string
+	UserID      int64
+	Timestamp   time.Time
+	Text        string
+	ReplySent   bool
+	MediaBase64 string
+}
+
+// Forwarder delivers an Event to one sink. Implementations should treat
+// Forward as best-effort for a single attempt; retrying is handled by
+// Pool.
+type Forwarder interface {
+	Forward(ctx context.Context, event Event) error
+}
+
+// defaultMaxRetries is how many times Pool attempts a single forwarder
+// for one event before giving up and logging it as dead-lettered.
+const defaultMaxRetries = 3
+
+// defaultQueueSize bounds how many events can be buffered before Submit
+// starts dropping them.
+const defaultQueueSize = 256
+
+// Pool fans events out to every registered Forwarder concurrently. A
+// failing sink does not stop delivery to the others, but events are
+// dispatched one at a time: the next event is not handed to any sink
+// until every sink has finished (including retries) with the current
+// one, so a slow or retrying sink delays later events for all sinks.
+type Pool struct {
+	forwarders []Forwarder
+	maxRetries int
+	queue      chan Event
+	wg         sync.WaitGroup
+}
+
+// NewPool returns a Pool that fans out to fws. maxRetries <= 0 uses
+// defaultMaxRetries.
+func NewPool(fws []Forwarder, maxRetries int) *Pool {
+	if maxRetries <= 0 {
+		maxRetries = defaultMaxRetries
+	}
+	return &Pool{
+		forwarders: fws,
+		maxRetries: maxRetries,
+		queue:      make(chan Event, defaultQueueSize),
+	}
+}
+
+// Start runs the pool's dispatch loop until ctx is cancelled.
+func (p *Pool) Start(ctx context.Context) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-p.queue:
+				p.dispatch(ctx, event)
+			}
+		}
+	}()
+}
+
+// Wait blocks until the dispatch loop started by Start has returned.
+func (p *Pool) Wait() {
+	p.wg.Wait()
+}
+
+// Submit enqueues event for delivery to every forwarder. It never
+// blocks: if the queue is full the event is dropped and logged.
+func (p *Pool) Submit(event Event) {
+	select {
+	case p.queue <- event:
+	default:
+		log.Printf("forwarders: queue full, dropping event for user %d", event.UserID)
+	}
+}
+
+// dispatch delivers event to every forwarder concurrently and waits for
+// all of them to finish (including retries) before returning.
+func (p *Pool) dispatch(ctx context.Context, event Event) {
+	var wg sync.WaitGroup
+	for _, fw := range p.forwarders {
+		wg.Add(1)
+		go func(fw Forwarder) {
+			defer wg.Done()
+			p.forwardWithRetry(ctx, fw, event)
+		}(fw)
+	}
+	wg.Wait()
+}
+
+// forwardWithRetry retries a single forwarder with exponential backoff,
+// logging (and giving up on) the event once maxRetries is exhausted.
+func (p *Pool) forwardWithRetry(ctx context.Context, fw Forwarder, event Event) {
+	backoff := time.Second
+
+	for attempt := 1; attempt <= p.maxRetries; attempt++ {
+		err := fw.Forward(ctx, event)
+		if err == nil {
+			return
+		}
+
+		if attempt == p.maxRetries {
+			log.Printf("forwarders: dead-letter: %T failed permanently for user %d (%s): %v", fw, event.UserID, event.SenderName, err)
+			return
+		}
+
+		log.Printf("forwarders: %T forward failed for user %d, retrying in %s (attempt %d/%d): %v", fw, event.UserID, backoff, attempt, p.maxRetries, err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		backoff *= 2
+	}
+}
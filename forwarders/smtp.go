@@ -0,0 +1,134 @@
+package forwarders
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/smtp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SMTPConfig configures an SMTPForwarder.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       string
+	// DigestInterval batches messages into one email every interval
+	// instead of sending one email per message. Zero disables batching.
+	DigestInterval time.Duration
+}
+
+// SMTPForwarder emails every forwarded message, either immediately or as
+// a periodic digest.
+type SMTPForwarder struct {
+	cfg SMTPConfig
+
+	mu      sync.Mutex
+	pending []Event
+}
+
+// NewSMTPForwarder returns an SMTPForwarder for cfg. If cfg.DigestInterval
+// is non-zero, call Run in its own goroutine to start the flusher.
+func NewSMTPForwarder(cfg SMTPConfig) *SMTPForwarder {
+	return &SMTPForwarder{cfg: cfg}
+}
+
+// Forward implements Forwarder.
+func (s *SMTPForwarder) Forward(ctx context.Context, event Event) error {
+	if s.cfg.DigestInterval <= 0 {
+		return s.send(fmt.Sprintf("Message from %s", sanitizeHeaderValue(event.SenderName)), formatEvent(event))
+	}
+
+	s.mu.Lock()
+	s.pending = append(s.pending, event)
+	s.mu.Unlock()
+	return nil
+}
+
+// Run periodically flushes buffered messages as a single digest email
+// until ctx is cancelled. It is a no-op when DigestInterval is zero.
+func (s *SMTPForwarder) Run(ctx context.Context) {
+	if s.cfg.DigestInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.cfg.DigestInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.flush(); err != nil {
+				log.Printf("forwarders: smtp digest flush failed: %v", err)
+			}
+		}
+	}
+}
+
+// flush sends and clears whatever events have been buffered since the
+// last flush.
+func (s *SMTPForwarder) flush() error {
+	s.mu.Lock()
+	events := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	var body strings.Builder
+	for _, event := range events {
+		body.WriteString(formatEvent(event))
+		body.WriteString("\n---\n")
+	}
+
+	return s.send(fmt.Sprintf("%d new message(s)", len(events)), body.String())
+}
+
+// send delivers a single email with the given subject and body. subject
+// is sanitized before use since it is built from attacker-controlled
+// data (the Telegram sender's display name), and a stray CR/LF in a
+// header value is enough to inject arbitrary extra headers.
+func (s *SMTPForwarder) send(subject, body string) error {
+	addr := fmt.Sprintf("%s:%d", s.cfg.Host, s.cfg.Port)
+
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+	}
+
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s",
+		s.cfg.From, s.cfg.To, sanitizeHeaderValue(subject), body)
+
+	if err := smtp.SendMail(addr, auth, s.cfg.From, []string{s.cfg.To}, []byte(msg)); err != nil {
+		return fmt.Errorf("smtp: send mail: %w", err)
+	}
+	return nil
+}
+
+// sanitizeHeaderValue strips CR and LF so attacker-controlled data (a
+// Telegram display name, message text) cannot inject extra headers or
+// terminate the header section early when interpolated into one.
+func sanitizeHeaderValue(s string) string {
+	s = strings.ReplaceAll(s, "\r", "")
+	s = strings.ReplaceAll(s, "\n", " ")
+	return s
+}
+
+// formatEvent renders an Event as plain text for an email body.
+func formatEvent(event Event) string {
+	status := "no reply sent"
+	if event.ReplySent {
+		status = "auto-reply already sent"
+	}
+	return fmt.Sprintf("From: %s (ID: %d)\nAt: %s\n%s\n\n%s",
+		event.SenderName, event.UserID, event.Timestamp.Format(time.RFC3339), status, event.Text)
+}
@@ -0,0 +1,64 @@
+package forwarders
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// WebhookForwarder POSTs a JSON-encoded Event to a configured URL,
+// signing the body with HMAC-SHA256 so the receiver can verify it.
+type WebhookForwarder struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+// NewWebhookForwarder returns a WebhookForwarder that posts to url,
+// signed with secret.
+func NewWebhookForwarder(url string, secret []byte) *WebhookForwarder {
+	return &WebhookForwarder{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Forward implements Forwarder.
+func (w *WebhookForwarder) Forward(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("webhook: marshal event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook: build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Signature-256", w.sign(body))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// sign computes the hex-encoded HMAC-SHA256 of body using w.secret.
+func (w *WebhookForwarder) sign(body []byte) string {
+	mac := hmac.New(sha256.New, w.secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
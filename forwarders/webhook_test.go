@@ -0,0 +1,56 @@
+package forwarders
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhookForwarderSignsBodyWithConfiguredSecret(t *testing.T) {
+	secret := []byte("shared-secret")
+	var gotBody []byte
+	var gotSignature string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		gotBody, err = io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("failed to read request body: %v", err)
+		}
+		gotSignature = r.Header.Get("X-Signature-256")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	fwd := NewWebhookForwarder(server.URL, secret)
+	event := Event{SenderName: "Alice", UserID: 42, Timestamp: time.Unix(0, 0), Text: "hi"}
+
+	if err := fwd.Forward(context.Background(), event); err != nil {
+		t.Fatalf("Forward returned an error: %v", err)
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(gotBody)
+	want := hex.EncodeToString(mac.Sum(nil))
+
+	if gotSignature != want {
+		t.Fatalf("signature mismatch: got %q, want %q", gotSignature, want)
+	}
+}
+
+func TestWebhookForwarderSignatureChangesWithSecret(t *testing.T) {
+	body := []byte(`{"text":"hi"}`)
+
+	fwdA := NewWebhookForwarder("http://example.invalid", []byte("secret-a"))
+	fwdB := NewWebhookForwarder("http://example.invalid", []byte("secret-b"))
+
+	if fwdA.sign(body) == fwdB.sign(body) {
+		t.Fatalf("signatures for the same body should differ when the secret differs")
+	}
+}
@@ -0,0 +1,63 @@
+package forwarders
+
+import (
+	"context"
+	"fmt"
+
+	"gosrc.io/xmpp"
+	"gosrc.io/xmpp/stanza"
+)
+
+// XMPPConfig configures an XMPPForwarder.
+type XMPPConfig struct {
+	JID       string
+	Password  string
+	Server    string
+	TargetJID string
+}
+
+// XMPPForwarder pushes forwarded messages to a configured JID, mirroring
+// the gateway pattern used by telegabber-style bridges.
+type XMPPForwarder struct {
+	cfg    XMPPConfig
+	client *xmpp.Client
+}
+
+// NewXMPPForwarder connects to the configured XMPP server and returns a
+// ready-to-use XMPPForwarder.
+func NewXMPPForwarder(cfg XMPPConfig) (*XMPPForwarder, error) {
+	client, err := xmpp.NewClient(&xmpp.Config{
+		TransportConfiguration: xmpp.TransportConfiguration{
+			Address: cfg.Server,
+		},
+		Jid:        cfg.JID,
+		Credential: xmpp.Password(cfg.Password),
+	}, xmpp.NewRouter(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("xmpp: create client: %w", err)
+	}
+
+	if err := client.Connect(); err != nil {
+		return nil, fmt.Errorf("xmpp: connect: %w", err)
+	}
+
+	return &XMPPForwarder{cfg: cfg, client: client}, nil
+}
+
+// Forward implements Forwarder.
+func (x *XMPPForwarder) Forward(ctx context.Context, event Event) error {
+	msg := stanza.Message{
+		Attrs: stanza.Attrs{To: x.cfg.TargetJID},
+		Body:  formatEvent(event),
+	}
+
+	if err := x.client.Send(msg); err != nil {
+		return fmt.Errorf("xmpp: send: %w", err)
+	}
+	return nil
+}
+
+// Close disconnects the underlying XMPP client.
+func (x *XMPPForwarder) Close() error {
+	return x.client.Disconnect()
+}
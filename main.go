@@ -1,16 +1,23 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"log"
 	"os"
+	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
+	"text/template"
 	"time"
 
+	"github.com/pquerna/otp/totp"
 	"gopkg.in/yaml.v3"
 
 	"github.com/gotd/td/telegram"
@@ -18,31 +25,90 @@ import (
 	"github.com/gotd/td/telegram/message"
 	"github.com/gotd/td/telegram/updates"
 	"github.com/gotd/td/tg"
+
+	"github.com/bogorad/no-more-telegram/forwarders"
+	"github.com/bogorad/no-more-telegram/store"
 )
 
 // Config holds the daemon configuration
 type Config struct {
-	AppID                 int    `yaml:"app_id" env:"APP_ID"`
-	AppHash               string `yaml:"app_hash" env:"APP_HASH"`
-	SessionFile           string `yaml:"session_file" env:"SESSION_FILE"`
-	Phone                 string `yaml:"phone" env:"PHONE"`
-	Password              string `yaml:"password" env:"PASSWORD"`
-	ResponseMsg           string `yaml:"response_message" env:"RESPONSE_MSG"`
-	ResponseTimeoutHours  int    `yaml:"response_timeout_hours" env:"RESPONSE_TIMEOUT_HOURS"`
-	LogLevel              string `yaml:"log_level" env:"LOG_LEVEL"`
-	LogFile               string `yaml:"log_file" env:"LOG_FILE"`
-	EnableDaemonMode      bool   `yaml:"enable_daemon_mode" env:"ENABLE_DAEMON_MODE"`
+	AppID                  int               `yaml:"app_id" env:"APP_ID"`
+	AppHash                string            `yaml:"app_hash" env:"APP_HASH"`
+	SessionFile            string            `yaml:"session_file" env:"SESSION_FILE"`
+	Phone                  string            `yaml:"phone" env:"PHONE"`
+	Password               string            `yaml:"password" env:"PASSWORD"`
+	PasswordTOTPSecret     string            `yaml:"password_totp_secret" env:"PASSWORD_TOTP_SECRET"`
+	PasswordCommand        string            `yaml:"password_command" env:"PASSWORD_COMMAND"`
+	ResponseMsg            string            `yaml:"response_message" env:"RESPONSE_MSG"`
+	ResponseTimeoutHours   int               `yaml:"response_timeout_hours" env:"RESPONSE_TIMEOUT_HOURS"`
+	LogLevel               string            `yaml:"log_level" env:"LOG_LEVEL"`
+	LogFile                string            `yaml:"log_file" env:"LOG_FILE"`
+	EnableDaemonMode       bool              `yaml:"enable_daemon_mode" env:"ENABLE_DAEMON_MODE"`
+	StoreFile              string            `yaml:"store_file" env:"STORE_FILE"`
+	StoreBackend           string            `yaml:"store_backend" env:"STORE_BACKEND"`
+	ContactsRefreshMinutes int               `yaml:"contacts_refresh_minutes" env:"CONTACTS_REFRESH_MINUTES"`
+	CoalesceWindowSeconds  int               `yaml:"coalesce_window_seconds" env:"COALESCE_WINDOW_SECONDS"`
+	Cleanup                CleanupConfig     `yaml:"cleanup"`
+	AdminAPI               AdminAPIConfig    `yaml:"admin_api"`
+	Forwarders             []ForwarderConfig `yaml:"forwarders"`
+}
+
+// ForwarderConfig describes one sink in the forwarders: list. Type
+// selects which fields apply: "smtp", "webhook" or "xmpp".
+type ForwarderConfig struct {
+	Type string `yaml:"type"`
+
+	// smtp
+	Host          string `yaml:"host"`
+	Port          int    `yaml:"port"`
+	Username      string `yaml:"username"`
+	Password      string `yaml:"password"`
+	From          string `yaml:"from"`
+	To            string `yaml:"to"`
+	DigestMinutes int    `yaml:"digest_minutes"`
+
+	// webhook
+	URL    string `yaml:"url"`
+	Secret string `yaml:"secret"`
+
+	// xmpp
+	JID       string `yaml:"jid"`
+	Server    string `yaml:"server"`
+	TargetJID string `yaml:"target_jid"`
+}
+
+// AdminAPIConfig controls the optional embedded HTTP admin server.
+type AdminAPIConfig struct {
+	Listen string `yaml:"listen" env:"ADMIN_API_LISTEN"`
+	Token  string `yaml:"token" env:"ADMIN_API_TOKEN"`
+}
+
+// CleanupConfig controls the optional startup cleanup subsystem, which
+// actually reduces the user's Telegram footprint instead of just
+// auto-replying to it.
+type CleanupConfig struct {
+	Enabled          bool `yaml:"enabled"`
+	LeaveGroups      bool `yaml:"leave_groups"`
+	LeaveChannels    bool `yaml:"leave_channels"`
+	ArchiveDialogs   bool `yaml:"archive_dialogs"`
+	MuteAll          bool `yaml:"mute_all"`
+	DeleteAfterReply bool `yaml:"delete_after_reply"`
+	DryRun           bool `yaml:"dry_run"`
 }
 
 // DefaultConfig returns a config with default values
 func DefaultConfig() *Config {
 	return &Config{
-		SessionFile:          "session.json",
-		ResponseMsg:          "Hi! I'm no longer using Telegram. Please contact me via email or other means.",
-		ResponseTimeoutHours: 24,
-		LogLevel:             "info",
-		LogFile:              "",
-		EnableDaemonMode:     false,
+		SessionFile:            "session.json",
+		ResponseMsg:            "Hi! I'm no longer using Telegram. Please contact me via email or other means.",
+		ResponseTimeoutHours:   24,
+		LogLevel:               "info",
+		LogFile:                "",
+		EnableDaemonMode:       false,
+		StoreFile:              "state.json",
+		StoreBackend:           "jsondb",
+		ContactsRefreshMinutes: 60,
+		CoalesceWindowSeconds:  2,
 	}
 }
 
@@ -82,6 +148,18 @@ func LoadConfig(configPath string) (*Config, error) {
 	if val := os.Getenv("PASSWORD"); val != "" {
 		config.Password = val
 	}
+	if val := os.Getenv("PASSWORD_TOTP_SECRET"); val != "" {
+		config.PasswordTOTPSecret = val
+	}
+	if val := os.Getenv("PASSWORD_COMMAND"); val != "" {
+		config.PasswordCommand = val
+	}
+	if val := os.Getenv("ADMIN_API_LISTEN"); val != "" {
+		config.AdminAPI.Listen = val
+	}
+	if val := os.Getenv("ADMIN_API_TOKEN"); val != "" {
+		config.AdminAPI.Token = val
+	}
 	if val := os.Getenv("RESPONSE_MSG"); val != "" {
 		config.ResponseMsg = val
 	}
@@ -99,6 +177,22 @@ func LoadConfig(configPath string) (*Config, error) {
 	if val := os.Getenv("ENABLE_DAEMON_MODE"); val != "" {
 		config.EnableDaemonMode = val == "true" || val == "1"
 	}
+	if val := os.Getenv("STORE_FILE"); val != "" {
+		config.StoreFile = val
+	}
+	if val := os.Getenv("STORE_BACKEND"); val != "" {
+		config.StoreBackend = val
+	}
+	if val := os.Getenv("CONTACTS_REFRESH_MINUTES"); val != "" {
+		if _, err := fmt.Sscanf(val, "%d", &config.ContactsRefreshMinutes); err != nil {
+			return nil, fmt.Errorf("invalid CONTACTS_REFRESH_MINUTES: %w", err)
+		}
+	}
+	if val := os.Getenv("COALESCE_WINDOW_SECONDS"); val != "" {
+		if _, err := fmt.Sscanf(val, "%d", &config.CoalesceWindowSeconds); err != nil {
+			return nil, fmt.Errorf("invalid COALESCE_WINDOW_SECONDS: %w", err)
+		}
+	}
 
 	return config, nil
 }
@@ -117,41 +211,182 @@ func (c *Config) Validate() error {
 	if c.ResponseTimeoutHours < 1 {
 		return fmt.Errorf("response_timeout_hours must be at least 1")
 	}
+	if c.ContactsRefreshMinutes < 1 {
+		return fmt.Errorf("contacts_refresh_minutes must be at least 1")
+	}
+	if c.CoalesceWindowSeconds < 1 {
+		return fmt.Errorf("coalesce_window_seconds must be at least 1")
+	}
+	if c.StoreBackend != "" && c.StoreBackend != "jsondb" && c.StoreBackend != "boltdb" {
+		return fmt.Errorf("store_backend must be %q or %q", "jsondb", "boltdb")
+	}
+
+	passwordOptions := 0
+	if c.Password != "" {
+		passwordOptions++
+	}
+	if c.PasswordTOTPSecret != "" {
+		passwordOptions++
+	}
+	if c.PasswordCommand != "" {
+		passwordOptions++
+	}
+	if passwordOptions > 1 {
+		return fmt.Errorf("password, password_totp_secret and password_command are mutually exclusive")
+	}
+
+	if c.AdminAPI.Listen != "" && c.AdminAPI.Token == "" {
+		return fmt.Errorf("admin_api.token is required when admin_api.listen is set")
+	}
+
 	return nil
 }
 
+// daemonMetrics holds the counters exposed by the admin API's /metrics
+// endpoint. All fields are accessed atomically since they are updated
+// from the update dispatcher and read from the HTTP server concurrently.
+type daemonMetrics struct {
+	messagesReceived int64
+	repliesSent      int64
+	floodWaitRetries int64
+}
+
+func (m *daemonMetrics) incMessagesReceived() { atomic.AddInt64(&m.messagesReceived, 1) }
+func (m *daemonMetrics) incRepliesSent()      { atomic.AddInt64(&m.repliesSent, 1) }
+func (m *daemonMetrics) incFloodWaitRetries() { atomic.AddInt64(&m.floodWaitRetries, 1) }
+
+// pendingReply buffers a burst of messages from a single sender so they
+// collapse into a single auto-reply instead of one per message.
+type pendingReply struct {
+	firstSeen  time.Time
+	count      int
+	senderName string
+	timer      *time.Timer
+}
+
 // TelegramDaemon represents the main daemon structure
 type TelegramDaemon struct {
 	config          *Config
 	client          *telegram.Client
+	store           store.Store
+	ctx             context.Context     // Root context, used by timers that outlive a single update
+	contactsMutex   sync.RWMutex        // Mutex for thread-safe access to contacts/contactsHash
 	contacts        map[int64]bool      // Cache of contact user IDs
+	contactsHash    int64               // Hash computed from the last full ContactsGetContacts result
 	respondedUsers  map[int64]time.Time // Track users who have been responded to
 	responseMutex   sync.RWMutex        // Mutex for thread-safe access to respondedUsers
 	responseTimeout time.Duration       // How long to wait before responding to the same user again
+	coalesceWindow  time.Duration       // How long to wait for a burst of messages to settle
+	pending         map[int64]*pendingReply
+	pendingMutex    sync.Mutex
+	startTime       time.Time
+	metrics         *daemonMetrics
+	adminAPI        *adminAPIServer
+	configPath      string
+	forwarderPool   *forwarders.Pool
 }
 
 // NewTelegramDaemon creates a new daemon instance
-func NewTelegramDaemon(config *Config) *TelegramDaemon {
-	return &TelegramDaemon{
+func NewTelegramDaemon(config *Config) (*TelegramDaemon, error) {
+	st, err := newStore(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize store: %w", err)
+	}
+
+	d := &TelegramDaemon{
 		config:          config,
+		store:           st,
 		contacts:        make(map[int64]bool),
 		respondedUsers:  make(map[int64]time.Time),
 		responseTimeout: time.Duration(config.ResponseTimeoutHours) * time.Hour,
+		coalesceWindow:  time.Duration(config.CoalesceWindowSeconds) * time.Second,
+		pending:         make(map[int64]*pendingReply),
+		startTime:       time.Now(),
+		metrics:         &daemonMetrics{},
+	}
+
+	if state, err := d.store.Load(); err != nil {
+		log.Printf("Failed to load persisted state from %s, starting fresh: %v", config.StoreFile, err)
+	} else {
+		d.respondedUsers = state.RespondedUsers
+		d.contactsHash = state.ContactsHash
+		for _, id := range state.ContactIDs {
+			d.contacts[id] = true
+		}
+		log.Printf("Loaded persisted state: %d responded users, %d cached contacts", len(d.respondedUsers), len(d.contacts))
+	}
+
+	return d, nil
+}
+
+// persistState saves the current in-memory state to the configured store.
+// It takes responseMutex and contactsMutex itself to snapshot the state,
+// so callers must not hold either of them. The store write is best-effort
+// and logged on failure.
+func (d *TelegramDaemon) persistState() {
+	d.responseMutex.RLock()
+	responded := make(map[int64]time.Time, len(d.respondedUsers))
+	for id, t := range d.respondedUsers {
+		responded[id] = t
+	}
+	d.responseMutex.RUnlock()
+
+	ids := d.getContactIDs()
+
+	d.contactsMutex.RLock()
+	contactsHash := d.contactsHash
+	d.contactsMutex.RUnlock()
+
+	state := &store.State{
+		RespondedUsers: responded,
+		ContactIDs:     ids,
+		ContactsHash:   contactsHash,
+	}
+	if err := d.store.Save(state); err != nil {
+		log.Printf("Failed to persist state: %v", err)
 	}
 }
 
 // authenticator handles the authentication flow
 type authenticator struct {
-	phone    string
-	password string
+	phone  string
+	config *Config
 }
 
 func (a *authenticator) Phone(ctx context.Context) (string, error) {
 	return a.phone, nil
 }
 
+// Password resolves the cloud password at the moment it is needed, rather
+// than once at startup, since a TOTP-derived password is only valid for a
+// short window.
 func (a *authenticator) Password(ctx context.Context) (string, error) {
-	return a.password, nil
+	return resolvePassword(ctx, a.config)
+}
+
+// resolvePassword returns the Telegram cloud password to use, computed
+// according to whichever of the mutually exclusive password options is
+// configured (see Config.Validate).
+func resolvePassword(ctx context.Context, c *Config) (string, error) {
+	switch {
+	case c.PasswordTOTPSecret != "":
+		code, err := totp.GenerateCode(c.PasswordTOTPSecret, time.Now())
+		if err != nil {
+			return "", fmt.Errorf("failed to generate TOTP code: %w", err)
+		}
+		return code, nil
+
+	case c.PasswordCommand != "":
+		cmd := exec.CommandContext(ctx, "sh", "-c", c.PasswordCommand)
+		out, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("password_command failed: %w", err)
+		}
+		return strings.TrimSpace(string(out)), nil
+
+	default:
+		return c.Password, nil
+	}
 }
 
 func (a *authenticator) Code(ctx context.Context, sentCode *tg.AuthSentCode) (string, error) {
@@ -175,12 +410,18 @@ func (a *authenticator) SignUp(ctx context.Context) (auth.UserInfo, error) {
 	return auth.UserInfo{}, fmt.Errorf("sign up not supported")
 }
 
-// loadContacts fetches and caches the user's contact list
+// loadContacts fetches and caches the user's contact list. It passes the
+// hash from the last successful fetch so Telegram can reply with
+// ContactsContactsNotModified instead of re-sending the full list.
 func (d *TelegramDaemon) loadContacts(ctx context.Context) error {
 	api := d.client.API()
-	
-	// Get contacts
-	contactsResult, err := api.ContactsGetContacts(ctx, 0) // Pass 0 directly as hash
+
+	d.contactsMutex.RLock()
+	lastHash := d.contactsHash
+	d.contactsMutex.RUnlock()
+
+	// Get contacts, reusing the hash from the last refresh
+	contactsResult, err := api.ContactsGetContacts(ctx, lastHash)
 	if err != nil {
 		return fmt.Errorf("failed to get contacts: %w", err)
 	}
@@ -189,23 +430,32 @@ func (d *TelegramDaemon) loadContacts(ctx context.Context) error {
 	switch contacts := contactsResult.(type) {
 	case *tg.ContactsContacts:
 		log.Printf("Loaded %d contacts", len(contacts.Contacts))
-		
-		// Clear existing contacts cache
-		d.contacts = make(map[int64]bool)
-		
-		// Add contacts to cache
+
+		// Build the replacement cache and its hash before swapping in,
+		// so readers never observe a partially-populated map.
+		newContacts := make(map[int64]bool, len(contacts.Contacts))
+		ids := make([]int64, 0, len(contacts.Contacts))
 		for _, contact := range contacts.Contacts {
-			d.contacts[contact.UserID] = true
+			newContacts[contact.UserID] = true
+			ids = append(ids, contact.UserID)
 		}
-		
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+		d.contactsMutex.Lock()
+		d.contacts = newContacts
+		d.contactsHash = computeContactsHash(ids)
+		d.contactsMutex.Unlock()
+
+		d.persistState()
+
 		// Log contact user IDs for debugging
-		if d.config.LogLevel == "debug" {
-			log.Printf("Contact user IDs: %v", d.getContactIDs())
+		if d.isDebugLogLevel() {
+			log.Printf("Contact user IDs: %v", ids)
 		}
-		
+
 	case *tg.ContactsContactsNotModified:
 		log.Println("Contacts not modified, using cached version")
-		
+
 	default:
 		return fmt.Errorf("unexpected contacts result type: %T", contacts)
 	}
@@ -213,65 +463,277 @@ func (d *TelegramDaemon) loadContacts(ctx context.Context) error {
 	return nil
 }
 
+// refreshContactsPeriodically refreshes the contacts cache every
+// config.ContactsRefreshMinutes until ctx is cancelled.
+func (d *TelegramDaemon) refreshContactsPeriodically(ctx context.Context) {
+	interval := time.Duration(d.config.ContactsRefreshMinutes) * time.Minute
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.loadContacts(ctx); err != nil {
+				log.Printf("Periodic contacts refresh failed: %v", err)
+			}
+			d.expungeStaleResponses()
+		}
+	}
+}
+
+// expungeStaleResponses drops respondedUsers entries older than
+// responseTimeout so the map does not grow without bound, and persists
+// the result.
+func (d *TelegramDaemon) expungeStaleResponses() {
+	d.responseMutex.Lock()
+	for id, t := range d.respondedUsers {
+		if time.Since(t) > d.responseTimeout {
+			delete(d.respondedUsers, id)
+		}
+	}
+	d.responseMutex.Unlock()
+
+	d.persistState()
+}
+
+// reloadHotConfig re-reads the YAML config file and hot-swaps the
+// fields that are safe to change without dropping the Telegram session:
+// ResponseMsg, ResponseTimeoutHours and LogLevel.
+func (d *TelegramDaemon) reloadHotConfig() error {
+	fresh, err := LoadConfig(d.configPath)
+	if err != nil {
+		return fmt.Errorf("failed to reload config: %w", err)
+	}
+	if err := fresh.Validate(); err != nil {
+		return fmt.Errorf("reloaded config is invalid: %w", err)
+	}
+
+	d.responseMutex.Lock()
+	d.config.ResponseMsg = fresh.ResponseMsg
+	d.config.ResponseTimeoutHours = fresh.ResponseTimeoutHours
+	d.config.LogLevel = fresh.LogLevel
+	d.responseTimeout = time.Duration(fresh.ResponseTimeoutHours) * time.Hour
+	d.responseMutex.Unlock()
+
+	log.Printf("Hot-reloaded config from %s", d.configPath)
+	return nil
+}
+
 // getContactIDs returns a slice of contact user IDs for debugging
 func (d *TelegramDaemon) getContactIDs() []int64 {
-	var ids []int64
+	d.contactsMutex.RLock()
+	defer d.contactsMutex.RUnlock()
+
+	ids := make([]int64, 0, len(d.contacts))
 	for id := range d.contacts {
 		ids = append(ids, id)
 	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
 	return ids
 }
 
+// computeContactsHash derives the client-side list hash Telegram expects
+// on the next ContactsGetContacts call. It implements the hash
+// recurrence documented at core.telegram.org/api/offsets#hash-generation,
+// which requires ids to be in the same (ascending) order Telegram itself
+// uses, or the same unchanged set will hash differently between calls.
+func computeContactsHash(ids []int64) int64 {
+	var hash uint64
+	for _, id := range ids {
+		hash ^= hash >> 21
+		hash ^= hash << 35
+		hash ^= hash >> 4
+		hash += uint64(id)
+	}
+	return int64(hash)
+}
+
 // isContact checks if a user ID is in the contacts list
 func (d *TelegramDaemon) isContact(userID int64) bool {
+	d.contactsMutex.RLock()
+	defer d.contactsMutex.RUnlock()
 	return d.contacts[userID]
 }
 
 // shouldRespond checks if we should respond to a user (rate limiting)
 func (d *TelegramDaemon) shouldRespond(userID int64) bool {
 	d.responseMutex.RLock()
+	defer d.responseMutex.RUnlock()
+
 	lastResponse, exists := d.respondedUsers[userID]
-	d.responseMutex.RUnlock()
-	
 	if !exists {
 		return true
 	}
-	
+
 	return time.Since(lastResponse) > d.responseTimeout
 }
 
+// isDebugLogLevel reports whether debug logging is enabled. It goes
+// through responseMutex since reloadHotConfig can hot-swap LogLevel
+// concurrently with the message-handling and timer goroutines reading it.
+func (d *TelegramDaemon) isDebugLogLevel() bool {
+	d.responseMutex.RLock()
+	defer d.responseMutex.RUnlock()
+	return d.config.LogLevel == "debug"
+}
+
+// responseMsgTemplate returns the current response_message template,
+// guarded the same way as isDebugLogLevel since reloadHotConfig can
+// hot-swap it concurrently.
+func (d *TelegramDaemon) responseMsgTemplate() string {
+	d.responseMutex.RLock()
+	defer d.responseMutex.RUnlock()
+	return d.config.ResponseMsg
+}
+
 // markUserResponded marks a user as having been responded to
 func (d *TelegramDaemon) markUserResponded(userID int64) {
 	d.responseMutex.Lock()
 	d.respondedUsers[userID] = time.Now()
 	d.responseMutex.Unlock()
+
+	d.persistState()
 }
 
 // sendResponse sends the predefined response message to a user
-func (d *TelegramDaemon) sendResponse(ctx context.Context, userID int64, userName string) error {
+func (d *TelegramDaemon) sendResponse(ctx context.Context, userID int64, userName string, messageCount int) error {
+	text, err := d.renderResponseMsg(messageCount)
+	if err != nil {
+		return fmt.Errorf("failed to render response message: %w", err)
+	}
+
 	// Create message sender
 	sender := message.NewSender(d.client.API())
-	
+
 	// Create input peer for the user
 	inputPeer := &tg.InputPeerUser{
 		UserID: userID,
 	}
-	
+
 	// Send the response message
-	_, err := sender.To(inputPeer).Text(ctx, d.config.ResponseMsg)
+	_, err = sender.To(inputPeer).Text(ctx, text)
 	if err != nil {
 		return fmt.Errorf("failed to send response to %s (ID: %d): %w", userName, userID, err)
 	}
-	
-	log.Printf("Sent response to %s (ID: %d): %s", userName, userID, d.config.ResponseMsg)
+
+	log.Printf("Sent response to %s (ID: %d): %s", userName, userID, text)
+	d.metrics.incRepliesSent()
 	return nil
 }
 
+// renderResponseMsg executes config.ResponseMsg as a text/template,
+// exposing the number of buffered messages as {{.MessageCount}}.
+func (d *TelegramDaemon) renderResponseMsg(messageCount int) (string, error) {
+	tmpl, err := template.New("response").Parse(d.responseMsgTemplate())
+	if err != nil {
+		return "", fmt.Errorf("invalid response_message template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, struct{ MessageCount int }{MessageCount: messageCount}); err != nil {
+		return "", fmt.Errorf("failed to execute response_message template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// bufferMessage arms or resets the coalescing timer for a sender so a
+// burst of messages within coalesce_window results in exactly one reply.
+func (d *TelegramDaemon) bufferMessage(userID int64, userName string) {
+	d.pendingMutex.Lock()
+	defer d.pendingMutex.Unlock()
+
+	if p, exists := d.pending[userID]; exists {
+		p.count++
+		p.timer.Reset(d.coalesceWindow)
+		return
+	}
+
+	d.pending[userID] = &pendingReply{
+		firstSeen:  time.Now(),
+		count:      1,
+		senderName: userName,
+		timer:      time.AfterFunc(d.coalesceWindow, func() { d.fireCoalescedReply(d.ctx, userID) }),
+	}
+}
+
+// fireCoalescedReply sends the single coalesced reply for a buffered
+// sender and marks them as responded to.
+func (d *TelegramDaemon) fireCoalescedReply(ctx context.Context, userID int64) {
+	d.pendingMutex.Lock()
+	p, exists := d.pending[userID]
+	if exists {
+		delete(d.pending, userID)
+	}
+	d.pendingMutex.Unlock()
+
+	if !exists {
+		return
+	}
+
+	if err := d.sendResponse(ctx, userID, p.senderName, p.count); err != nil {
+		log.Printf("Error sending coalesced response to %s (ID: %d): %v", p.senderName, userID, err)
+		return
+	}
+
+	d.markUserResponded(userID)
+
+	if d.config.Cleanup.Enabled && d.config.Cleanup.DeleteAfterReply {
+		if err := d.deleteHistoryAfterReply(ctx, userID); err != nil {
+			log.Printf("Failed to delete history with %s (ID: %d) after reply: %v", p.senderName, userID, err)
+		}
+	}
+}
+
+// drainPendingOnCancel flushes any buffered replies as soon as the root
+// context is cancelled, instead of letting them vanish with the process.
+func (d *TelegramDaemon) drainPendingOnCancel(ctx context.Context) {
+	<-ctx.Done()
+
+	d.pendingMutex.Lock()
+	ids := make([]int64, 0, len(d.pending))
+	for userID, p := range d.pending {
+		p.timer.Stop()
+		ids = append(ids, userID)
+	}
+	d.pendingMutex.Unlock()
+
+	// The root context is already cancelled, so use a short-lived one of
+	// our own to get these last replies out.
+	flushCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, userID := range ids {
+		d.fireCoalescedReply(flushCtx, userID)
+	}
+}
+
 // Start initializes and starts the daemon
 func (d *TelegramDaemon) Start(ctx context.Context) error {
+	d.ctx = ctx
+	go d.drainPendingOnCancel(ctx)
+
+	if d.config.AdminAPI.Listen != "" {
+		d.adminAPI = newAdminAPIServer(d)
+		go d.adminAPI.Run()
+	}
+
+	if len(d.config.Forwarders) > 0 {
+		fws, smtpForwarders, err := buildForwarders(d.config.Forwarders)
+		if err != nil {
+			return fmt.Errorf("failed to configure forwarders: %w", err)
+		}
+		d.forwarderPool = forwarders.NewPool(fws, 3)
+		d.forwarderPool.Start(ctx)
+		for _, s := range smtpForwarders {
+			go s.Run(ctx)
+		}
+	}
+
 	// Create update dispatcher
 	dispatcher := tg.NewUpdateDispatcher()
-	
+
 	// Create gaps handler for updates
 	gaps := updates.New(updates.Config{
 		Handler: dispatcher,
@@ -288,8 +750,8 @@ func (d *TelegramDaemon) Start(ctx context.Context) error {
 	// Setup authentication flow
 	flow := auth.NewFlow(
 		&authenticator{
-			phone:    d.config.Phone,
-			password: d.config.Password,
+			phone:  d.config.Phone,
+			config: d.config,
 		},
 		auth.SendCodeOptions{},
 	)
@@ -317,6 +779,16 @@ func (d *TelegramDaemon) Start(ctx context.Context) error {
 			return fmt.Errorf("failed to load contacts: %w", err)
 		}
 
+		// Run the one-time cleanup subsystem, if configured
+		if d.config.Cleanup.Enabled {
+			if err := d.runCleanup(ctx); err != nil {
+				log.Printf("Cleanup subsystem failed: %v", err)
+			}
+		}
+
+		// Periodically refresh contacts and expunge stale response records
+		go d.refreshContactsPeriodically(ctx)
+
 		// Start gaps handler
 		return gaps.Run(ctx, d.client.API(), self.ID, updates.AuthOptions{
 			OnStart: func(ctx context.Context) {
@@ -326,6 +798,51 @@ func (d *TelegramDaemon) Start(ctx context.Context) error {
 	})
 }
 
+// buildForwarders instantiates a Forwarder for every entry in cfgs. It
+// also returns the SMTP forwarders separately since those need their
+// digest flusher run in its own goroutine.
+func buildForwarders(cfgs []ForwarderConfig) ([]forwarders.Forwarder, []*forwarders.SMTPForwarder, error) {
+	var all []forwarders.Forwarder
+	var smtpForwarders []*forwarders.SMTPForwarder
+
+	for _, cfg := range cfgs {
+		switch cfg.Type {
+		case "smtp":
+			fw := forwarders.NewSMTPForwarder(forwarders.SMTPConfig{
+				Host:           cfg.Host,
+				Port:           cfg.Port,
+				Username:       cfg.Username,
+				Password:       cfg.Password,
+				From:           cfg.From,
+				To:             cfg.To,
+				DigestInterval: time.Duration(cfg.DigestMinutes) * time.Minute,
+			})
+			all = append(all, fw)
+			smtpForwarders = append(smtpForwarders, fw)
+
+		case "webhook":
+			all = append(all, forwarders.NewWebhookForwarder(cfg.URL, []byte(cfg.Secret)))
+
+		case "xmpp":
+			fw, err := forwarders.NewXMPPForwarder(forwarders.XMPPConfig{
+				JID:       cfg.JID,
+				Password:  cfg.Password,
+				Server:    cfg.Server,
+				TargetJID: cfg.TargetJID,
+			})
+			if err != nil {
+				return nil, nil, fmt.Errorf("xmpp forwarder: %w", err)
+			}
+			all = append(all, fw)
+
+		default:
+			return nil, nil, fmt.Errorf("unknown forwarder type: %q", cfg.Type)
+		}
+	}
+
+	return all, smtpForwarders, nil
+}
+
 // setupMessageHandlers configures the message handlers
 func (d *TelegramDaemon) setupMessageHandlers(dispatcher *tg.UpdateDispatcher) {
 	// Handle new private messages
@@ -334,7 +851,7 @@ func (d *TelegramDaemon) setupMessageHandlers(dispatcher *tg.UpdateDispatcher) {
 	})
 
 	// Handle new channel messages (optional, for debugging)
-	if d.config.LogLevel == "debug" {
+	if d.isDebugLogLevel() {
 		dispatcher.OnNewChannelMessage(func(ctx context.Context, e tg.Entities, update *tg.UpdateNewChannelMessage) error {
 			log.Printf("Channel message received: %+v", update.Message)
 			return nil
@@ -346,7 +863,7 @@ func (d *TelegramDaemon) setupMessageHandlers(dispatcher *tg.UpdateDispatcher) {
 func (d *TelegramDaemon) handleNewMessage(ctx context.Context, e tg.Entities, update *tg.UpdateNewMessage) error {
 	message, ok := update.Message.(*tg.Message)
 	if !ok {
-		if d.config.LogLevel == "debug" {
+		if d.isDebugLogLevel() {
 			log.Printf("Received non-message update: %T", update.Message)
 		}
 		return nil
@@ -354,7 +871,7 @@ func (d *TelegramDaemon) handleNewMessage(ctx context.Context, e tg.Entities, up
 
 	// Skip outgoing messages (messages sent by us)
 	if message.Out {
-		if d.config.LogLevel == "debug" {
+		if d.isDebugLogLevel() {
 			log.Printf("Skipping outgoing message")
 		}
 		return nil
@@ -367,26 +884,26 @@ func (d *TelegramDaemon) handleNewMessage(ctx context.Context, e tg.Entities, up
 	switch peer := message.PeerID.(type) {
 	case *tg.PeerUser:
 		senderID = peer.UserID
-		
+
 		// Get user info from entities
 		if user, exists := e.Users[peer.UserID]; exists {
 			senderName = fmt.Sprintf("%s %s", user.FirstName, user.LastName)
 		}
-		
+
 	case *tg.PeerChat:
-		if d.config.LogLevel == "debug" {
+		if d.isDebugLogLevel() {
 			log.Printf("Ignoring group chat message from chat ID: %d", peer.ChatID)
 		}
 		return nil
-		
+
 	case *tg.PeerChannel:
-		if d.config.LogLevel == "debug" {
+		if d.isDebugLogLevel() {
 			log.Printf("Ignoring channel message from channel ID: %d", peer.ChannelID)
 		}
 		return nil
-		
+
 	default:
-		if d.config.LogLevel == "debug" {
+		if d.isDebugLogLevel() {
 			log.Printf("Unknown peer type: %T", peer)
 		}
 		return nil
@@ -394,23 +911,29 @@ func (d *TelegramDaemon) handleNewMessage(ctx context.Context, e tg.Entities, up
 
 	// Log the message details
 	log.Printf("Message from %s (ID: %d): %s", senderName, senderID, message.Message)
+	d.metrics.incMessagesReceived()
+
+	// Mirror every inbound private message to the configured forwarders,
+	// regardless of whether the sender is a contact.
+	if d.forwarderPool != nil {
+		d.forwarderPool.Submit(forwarders.Event{
+			SenderName:  senderName,
+			UserID:      senderID,
+			Timestamp:   time.Now(),
+			Text:        message.Message,
+			ReplySent:   d.isContact(senderID) && !d.shouldRespond(senderID),
+			MediaBase64: d.extractMediaBase64(ctx, message),
+		})
+	}
 
 	// Check if sender is a contact
 	if d.isContact(senderID) {
 		log.Printf("Message from contact %s (ID: %d)", senderName, senderID)
-		
+
 		// Check if we should respond (rate limiting)
 		if d.shouldRespond(senderID) {
-			log.Printf("Sending response to %s (ID: %d)", senderName, senderID)
-			
-			// Send the response
-			if err := d.sendResponse(ctx, senderID, senderName); err != nil {
-				log.Printf("Error sending response: %v", err)
-				return err
-			}
-			
-			// Mark user as responded to
-			d.markUserResponded(senderID)
+			log.Printf("Buffering message from %s (ID: %d) for coalesced response", senderName, senderID)
+			d.bufferMessage(senderID, senderName)
 		} else {
 			log.Printf("Already responded to %s (ID: %d) recently, skipping", senderID)
 		}
@@ -472,7 +995,12 @@ func main() {
 	log.Printf("Starting Telegram daemon with config from: %s", configPath)
 
 	// Create daemon
-	daemon := NewTelegramDaemon(config)
+	daemon, err := NewTelegramDaemon(config)
+	if err != nil {
+		log.Fatalf("Failed to create daemon: %v", err)
+	}
+	daemon.configPath = configPath
+	defer daemon.store.Close()
 
 	// Setup signal handling for graceful shutdown
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
@@ -484,7 +1012,13 @@ func main() {
 		log.Fatalf("Daemon failed: %v", err)
 	}
 
+	if daemon.adminAPI != nil {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := daemon.adminAPI.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Admin API shutdown error: %v", err)
+		}
+		shutdownCancel()
+	}
+
 	log.Println("Telegram daemon stopped")
 }
-
-
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComputeContactsHashStableAcrossCalls(t *testing.T) {
+	ids := []int64{1, 2, 3, 100}
+
+	first := computeContactsHash(ids)
+	second := computeContactsHash(ids)
+
+	if first != second {
+		t.Fatalf("hash is not stable for the same contact set: %d != %d", first, second)
+	}
+}
+
+func TestComputeContactsHashSameOnceSorted(t *testing.T) {
+	ascending := []int64{1, 2, 3, 100}
+	shuffled := []int64{100, 1, 3, 2}
+
+	ascendingHash := computeContactsHash(ascending)
+
+	if got := computeContactsHash(sortInt64s(shuffled)); got != ascendingHash {
+		t.Fatalf("hash depends on input order once sorted: got %d, want %d", got, ascendingHash)
+	}
+}
+
+func TestComputeContactsHashChangesWithContactSet(t *testing.T) {
+	a := computeContactsHash([]int64{1, 2, 3})
+	b := computeContactsHash([]int64{1, 2, 3, 4})
+
+	if a == b {
+		t.Fatalf("hash did not change when the contact set changed")
+	}
+}
+
+func TestBufferMessageCoalescesRepeatedSenders(t *testing.T) {
+	d := &TelegramDaemon{
+		pending:        make(map[int64]*pendingReply),
+		coalesceWindow: time.Hour, // long enough that the timer never fires during the test
+	}
+
+	d.bufferMessage(42, "Alice")
+	d.bufferMessage(42, "Alice")
+	d.bufferMessage(42, "Alice")
+
+	d.pendingMutex.Lock()
+	p, exists := d.pending[42]
+	if exists {
+		p.timer.Stop()
+	}
+	d.pendingMutex.Unlock()
+
+	if !exists {
+		t.Fatalf("expected a pending reply to be buffered for user 42")
+	}
+	if p.count != 3 {
+		t.Fatalf("expected 3 buffered messages to coalesce into one pending reply, got count %d", p.count)
+	}
+}
+
+func TestBufferMessageTracksSendersIndependently(t *testing.T) {
+	d := &TelegramDaemon{
+		pending:        make(map[int64]*pendingReply),
+		coalesceWindow: time.Hour,
+	}
+
+	d.bufferMessage(1, "Alice")
+	d.bufferMessage(2, "Bob")
+
+	d.pendingMutex.Lock()
+	defer d.pendingMutex.Unlock()
+	for _, p := range d.pending {
+		p.timer.Stop()
+	}
+
+	if len(d.pending) != 2 {
+		t.Fatalf("expected 2 independently buffered senders, got %d", len(d.pending))
+	}
+}
+
+// sortInt64s is a small test helper mirroring the sort.Slice call
+// loadContacts makes before hashing.
+func sortInt64s(ids []int64) []int64 {
+	sorted := make([]int64, len(ids))
+	copy(sorted, ids)
+	for i := 1; i < len(sorted); i++ {
+		for j := i; j > 0 && sorted[j-1] > sorted[j]; j-- {
+			sorted[j-1], sorted[j] = sorted[j], sorted[j-1]
+		}
+	}
+	return sorted
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"log"
+
+	"github.com/gotd/td/telegram/downloader"
+	"github.com/gotd/td/tg"
+)
+
+// extractMediaBase64 downloads the photo or document attached to msg, if
+// any, and returns it base64-encoded for inclusion in a forwarders.Event.
+// It returns "" for messages with no media, for media this daemon doesn't
+// know how to fetch (logged at debug level), and for failed downloads
+// (logged), rather than failing the whole forward.
+func (d *TelegramDaemon) extractMediaBase64(ctx context.Context, msg *tg.Message) string {
+	if msg.Media == nil {
+		return ""
+	}
+
+	loc, ok := mediaFileLocation(msg.Media)
+	if !ok {
+		if d.isDebugLogLevel() {
+			log.Printf("Skipping forward of unsupported media type: %T", msg.Media)
+		}
+		return ""
+	}
+
+	var buf bytes.Buffer
+	dl := downloader.NewDownloader()
+	if _, err := dl.Download(d.client.API(), loc).Stream(ctx, &buf); err != nil {
+		log.Printf("Failed to download message media: %v", err)
+		return ""
+	}
+
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+// mediaFileLocation returns the InputFileLocation needed to download the
+// largest regular (non-progressive) rendition of a photo, or a document
+// as-is.
+func mediaFileLocation(media tg.MessageMediaClass) (tg.InputFileLocationClass, bool) {
+	switch m := media.(type) {
+	case *tg.MessageMediaPhoto:
+		photo, ok := m.Photo.(*tg.Photo)
+		if !ok || len(photo.Sizes) == 0 {
+			return nil, false
+		}
+		largest := largestPhotoSize(photo.Sizes)
+		if largest == "" {
+			return nil, false
+		}
+		return &tg.InputPhotoFileLocation{
+			ID:            photo.ID,
+			AccessHash:    photo.AccessHash,
+			FileReference: photo.FileReference,
+			ThumbSize:     largest,
+		}, true
+
+	case *tg.MessageMediaDocument:
+		doc, ok := m.Document.(*tg.Document)
+		if !ok {
+			return nil, false
+		}
+		return &tg.InputDocumentFileLocation{
+			ID:            doc.ID,
+			AccessHash:    doc.AccessHash,
+			FileReference: doc.FileReference,
+		}, true
+
+	default:
+		return nil, false
+	}
+}
+
+// largestPhotoSize picks the biggest regular (non-progressive) photo
+// size by its reported dimensions, returning its Type identifier.
+func largestPhotoSize(sizes []tg.PhotoSizeClass) string {
+	var (
+		bestType string
+		bestArea int
+	)
+	for _, size := range sizes {
+		s, ok := size.(*tg.PhotoSize)
+		if !ok {
+			continue
+		}
+		area := s.W * s.H
+		if area > bestArea {
+			bestArea = area
+			bestType = s.Type
+		}
+	}
+	return bestType
+}
@@ -0,0 +1,81 @@
+//go:build boltdb
+
+// Package boltdb is an optional store.Store implementation backed by
+// go.etcd.io/bbolt. It is only compiled in when building with the
+// "boltdb" build tag, since most deployments are happy with the default
+// jsondb backend and shouldn't need to pull in the extra dependency.
+package boltdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/bogorad/no-more-telegram/store"
+)
+
+var bucketName = []byte("state")
+var stateKey = []byte("state")
+
+// DB is a BoltDB-backed store.Store.
+type DB struct {
+	db *bolt.DB
+}
+
+// New opens (creating if necessary) the Bolt database at path.
+func New(path string) (*DB, error) {
+	db, err := bolt.Open(path, 0o600, nil)
+	if err != nil {
+		return nil, fmt.Errorf("boltdb: open %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucketName)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("boltdb: create bucket: %w", err)
+	}
+
+	return &DB{db: db}, nil
+}
+
+// Load implements store.Store.
+func (d *DB) Load() (*store.State, error) {
+	state := store.NewState()
+
+	err := d.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(bucketName).Get(stateKey)
+		if data == nil {
+			return nil
+		}
+		return json.Unmarshal(data, state)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("boltdb: load state: %w", err)
+	}
+	if state.RespondedUsers == nil {
+		state.RespondedUsers = make(map[int64]time.Time)
+	}
+	return state, nil
+}
+
+// Save implements store.Store.
+func (d *DB) Save(state *store.State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("boltdb: marshal state: %w", err)
+	}
+
+	return d.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketName).Put(stateKey, data)
+	})
+}
+
+// Close implements store.Store.
+func (d *DB) Close() error {
+	return d.db.Close()
+}
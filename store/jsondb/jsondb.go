@@ -0,0 +1,81 @@
+// Package jsondb is the default store.Store implementation: it keeps the
+// daemon state in a single JSON file on disk.
+package jsondb
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/bogorad/no-more-telegram/store"
+)
+
+// DB is a JSON-file-backed store.Store.
+type DB struct {
+	path string
+	mu   sync.Mutex
+}
+
+// New returns a DB that reads and writes state to path. The file and its
+// parent directory are created on first Save if they do not exist.
+func New(path string) *DB {
+	return &DB{path: path}
+}
+
+// Load implements store.Store.
+func (d *DB) Load() (*store.State, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	data, err := os.ReadFile(d.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store.NewState(), nil
+		}
+		return nil, fmt.Errorf("jsondb: read %s: %w", d.path, err)
+	}
+
+	state := store.NewState()
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("jsondb: parse %s: %w", d.path, err)
+	}
+	if state.RespondedUsers == nil {
+		state.RespondedUsers = make(map[int64]time.Time)
+	}
+	return state, nil
+}
+
+// Save implements store.Store. It writes to a temp file and renames it
+// into place so a crash mid-write cannot corrupt the existing state.
+func (d *DB) Save(state *store.State) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if dir := filepath.Dir(d.path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("jsondb: mkdir %s: %w", dir, err)
+		}
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("jsondb: marshal state: %w", err)
+	}
+
+	tmp := d.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("jsondb: write %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, d.path); err != nil {
+		return fmt.Errorf("jsondb: rename %s to %s: %w", tmp, d.path, err)
+	}
+	return nil
+}
+
+// Close implements store.Store. The JSON backend holds no resources.
+func (d *DB) Close() error {
+	return nil
+}
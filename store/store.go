@@ -0,0 +1,39 @@
+// Package store defines the persistence interface used by the daemon to
+// survive restarts without re-spamming contacts or re-fetching the full
+// contact list.
+package store
+
+import "time"
+
+// State is the full set of daemon data that needs to survive a restart.
+type State struct {
+	// RespondedUsers maps a user ID to the time they last received the
+	// auto-reply.
+	RespondedUsers map[int64]time.Time `json:"responded_users"`
+	// ContactIDs is the cached set of Telegram contact user IDs.
+	ContactIDs []int64 `json:"contact_ids"`
+	// ContactsHash is the hash computed from the contact list fetched by
+	// the last successful ContactsGetContacts call, passed back on the
+	// next call so Telegram can reply with ContactsContactsNotModified
+	// when nothing changed.
+	ContactsHash int64 `json:"contacts_hash"`
+}
+
+// NewState returns an empty, ready-to-use State.
+func NewState() *State {
+	return &State{
+		RespondedUsers: make(map[int64]time.Time),
+	}
+}
+
+// Store persists and reloads daemon State. Implementations must be safe
+// for concurrent use.
+type Store interface {
+	// Load returns the last saved State. Implementations return a fresh
+	// NewState() (not an error) when no prior state exists on disk.
+	Load() (*State, error)
+	// Save persists the given State, replacing whatever was saved before.
+	Save(*State) error
+	// Close releases any resources held by the store.
+	Close() error
+}
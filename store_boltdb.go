@@ -0,0 +1,27 @@
+//go:build boltdb
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/bogorad/no-more-telegram/store"
+	"github.com/bogorad/no-more-telegram/store/boltdb"
+	"github.com/bogorad/no-more-telegram/store/jsondb"
+)
+
+// newStore builds the store.Store backend selected by config.StoreBackend.
+func newStore(config *Config) (store.Store, error) {
+	switch config.StoreBackend {
+	case "", "jsondb":
+		return jsondb.New(config.StoreFile), nil
+	case "boltdb":
+		db, err := boltdb.New(config.StoreFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open boltdb store: %w", err)
+		}
+		return db, nil
+	default:
+		return nil, fmt.Errorf("unknown store_backend: %q", config.StoreBackend)
+	}
+}
@@ -0,0 +1,25 @@
+//go:build !boltdb
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/bogorad/no-more-telegram/store"
+	"github.com/bogorad/no-more-telegram/store/jsondb"
+)
+
+// newStore builds the store.Store backend selected by config.StoreBackend.
+// This build excludes the boltdb backend (it pulls in go.etcd.io/bbolt,
+// gated behind the "boltdb" build tag), so selecting it here is reported
+// as a configuration error rather than failing to compile.
+func newStore(config *Config) (store.Store, error) {
+	switch config.StoreBackend {
+	case "", "jsondb":
+		return jsondb.New(config.StoreFile), nil
+	case "boltdb":
+		return nil, fmt.Errorf("store_backend %q requires building with -tags boltdb", config.StoreBackend)
+	default:
+		return nil, fmt.Errorf("unknown store_backend: %q", config.StoreBackend)
+	}
+}